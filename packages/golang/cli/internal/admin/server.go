@@ -0,0 +1,216 @@
+// Package admin exposes a local, Clash-style control API for a running
+// tunnel: connection status, reconnect attempts, the last error, a
+// per-request log, aggregate counters, and the parsed ServiceToken
+// metadata. It learns the live state by subscribing to the tunnel
+// client's events.Bus, so it never needs a reference to the client
+// itself; the token metadata comes from Options.Token.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cascade-protocol/splits/cli/internal/config"
+	"github.com/cascade-protocol/splits/cli/internal/events"
+)
+
+// maxRequestLog caps how many recent requests are kept in memory for
+// /status. /requests does not replay this log; it streams only events
+// that arrive after a client subscribes.
+const maxRequestLog = 200
+
+// RequestLogEntry is one row of the per-request log.
+type RequestLogEntry struct {
+	RequestID  string    `json:"requestId"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	DurationMs int64     `json:"durationMs"`
+	At         time.Time `json:"at"`
+}
+
+// Counters are aggregate tunnel statistics since the admin server
+// started.
+type Counters struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+	BytesIn  uint64 `json:"bytesIn"`
+	BytesOut uint64 `json:"bytesOut"`
+}
+
+// Status is the live connection state returned by /status.
+type Status struct {
+	Connected         bool              `json:"connected"`
+	ReconnectAttempts int               `json:"reconnectAttempts"`
+	LastError         string            `json:"lastError,omitempty"`
+	LastErrorAt       time.Time         `json:"lastErrorAt,omitempty"`
+	Counters          Counters          `json:"counters"`
+	RecentRequests    []RequestLogEntry `json:"recentRequests"`
+}
+
+// Options configures the admin server.
+type Options struct {
+	// ListenAddr is the address to bind, e.g. "127.0.0.1:9090".
+	ListenAddr string
+	Token      *config.ParsedToken
+	Events     *events.Bus
+	// Disconnect, if set, is called when a client POSTs /disconnect,
+	// triggering a graceful shutdown of the tunnel.
+	Disconnect func()
+}
+
+// Server is the local admin/status HTTP API for a running tunnel.
+type Server struct {
+	opts Options
+
+	mu         sync.Mutex
+	status     Status
+	requestLog []RequestLogEntry
+
+	sseMu      sync.Mutex
+	sseClients map[chan []byte]struct{}
+}
+
+// New creates an admin Server. Call Run to start it.
+func New(opts Options) *Server {
+	return &Server{
+		opts:       opts,
+		sseClients: make(map[chan []byte]struct{}),
+	}
+}
+
+// Run subscribes to opts.Events, starts the HTTP server, and blocks
+// until ctx is cancelled or the server fails to start.
+func (s *Server) Run(ctx context.Context) error {
+	sub := s.opts.Events.Subscribe()
+	go s.consumeEvents(ctx, sub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/requests", s.handleRequests)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/disconnect", s.handleDisconnect)
+
+	srv := &http.Server{Addr: s.opts.ListenAddr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("admin server: %w", err)
+		}
+		return nil
+	}
+}
+
+func (s *Server) consumeEvents(ctx context.Context, sub <-chan events.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-sub:
+			s.apply(e)
+			s.broadcastSSE(e)
+		}
+	}
+}
+
+func (s *Server) apply(e events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e.Kind {
+	case events.Connected:
+		s.status.Connected = true
+		s.status.ReconnectAttempts = 0
+	case events.Disconnected:
+		s.status.Connected = false
+	case events.Reconnecting:
+		s.status.ReconnectAttempts = e.Attempt
+	case events.Error:
+		s.status.LastError = e.Err.Error()
+		s.status.LastErrorAt = e.At
+		s.status.Counters.Errors++
+	case events.Request:
+		s.status.Counters.Requests++
+	case events.Response:
+		s.status.Counters.BytesIn += uint64(e.BytesIn)
+		s.status.Counters.BytesOut += uint64(e.BytesOut)
+		if e.Status >= 500 {
+			s.status.Counters.Errors++
+		}
+
+		s.requestLog = append(s.requestLog, RequestLogEntry{
+			RequestID:  e.RequestID,
+			Method:     e.Method,
+			Path:       e.Path,
+			Status:     e.Status,
+			DurationMs: e.Duration.Milliseconds(),
+			At:         e.At,
+		})
+		if len(s.requestLog) > maxRequestLog {
+			s.requestLog = s.requestLog[len(s.requestLog)-maxRequestLog:]
+		}
+		s.status.RecentRequests = s.requestLog
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status := s.status
+	s.mu.Unlock()
+
+	writeJSON(w, status)
+}
+
+// configView is the redacted subset of config.ParsedToken served by
+// /config: parsed metadata only, never the raw token or its signature,
+// since this API is unauthenticated on 127.0.0.1 and reachable by any
+// local process.
+type configView struct {
+	ServiceID   string `json:"serviceId"`
+	ServiceName string `json:"serviceName"`
+	TunnelURL   string `json:"tunnelUrl"`
+	SplitConfig string `json:"splitConfig"`
+	SplitVault  string `json:"splitVault"`
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	t := s.opts.Token
+	writeJSON(w, configView{
+		ServiceID:   t.Payload.ServiceID,
+		ServiceName: t.ServiceName,
+		TunnelURL:   t.TunnelURL,
+		SplitConfig: t.Payload.SplitConfig,
+		SplitVault:  t.Payload.SplitVault,
+	})
+}
+
+func (s *Server) handleDisconnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.opts.Disconnect != nil {
+		s.opts.Disconnect()
+	}
+	writeJSON(w, map[string]string{"status": "disconnecting"})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cascade-protocol/splits/cli/internal/events"
+)
+
+// sseEvent is what /requests streams to subscribers: a flattened view of
+// the Request/Response events flowing through the tunnel.
+type sseEvent struct {
+	Kind       string    `json:"kind"`
+	RequestID  string    `json:"requestId"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status,omitempty"`
+	DurationMs int64     `json:"durationMs,omitempty"`
+	BytesIn    int       `json:"bytesIn,omitempty"`
+	BytesOut   int       `json:"bytesOut,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// handleRequests streams Request/Response events as Server-Sent Events
+// for as long as the client stays connected.
+func (s *Server) handleRequests(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan []byte, 16)
+	s.sseMu.Lock()
+	s.sseClients[ch] = struct{}{}
+	s.sseMu.Unlock()
+	defer func() {
+		s.sseMu.Lock()
+		delete(s.sseClients, ch)
+		s.sseMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) broadcastSSE(e events.Event) {
+	kind := ""
+	switch e.Kind {
+	case events.Request:
+		kind = "request"
+	case events.Response:
+		kind = "response"
+	default:
+		return
+	}
+
+	data, err := json.Marshal(sseEvent{
+		Kind:       kind,
+		RequestID:  e.RequestID,
+		Method:     e.Method,
+		Path:       e.Path,
+		Status:     e.Status,
+		DurationMs: e.Duration.Milliseconds(),
+		BytesIn:    e.BytesIn,
+		BytesOut:   e.BytesOut,
+		At:         e.At,
+	})
+	if err != nil {
+		return
+	}
+
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	for ch := range s.sseClients {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
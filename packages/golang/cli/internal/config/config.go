@@ -4,13 +4,41 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+// IssuerPubKey is the hex-encoded Ed25519 public key used to verify a
+// token's signature when its Kid is empty. It's baked in at build time:
+//
+//	-ldflags "-X github.com/cascade-protocol/splits/cli/internal/config.IssuerPubKey=<hex>"
+//
+// and can be overridden at runtime (local builds, dev tokens) via the
+// CASCADE_ISSUER_PUBKEY environment variable.
+var IssuerPubKey string
+
+// IssuerPubKeys holds additional issuer public keys for rotation, as a
+// comma-separated list of "kid:hexkey" pairs, also baked in via ldflags:
+//
+//	-ldflags "-X .../config.IssuerPubKeys=<kid>:<hex>,<kid2>:<hex2>"
+//
+// A token naming one of these kids in its Kid field is verified against
+// the matching key instead of IssuerPubKey.
+var IssuerPubKeys string
+
+// ErrBadSignature is returned when a token's signature does not verify
+// against a known issuer public key.
+var ErrBadSignature = errors.New("token signature verification failed")
+
 // ServiceToken represents the decoded token payload.
 type ServiceToken struct {
 	ServiceID   string `json:"serviceId"`
@@ -19,6 +47,9 @@ type ServiceToken struct {
 	Price       int64  `json:"price,string"`
 	CreatedAt   int64  `json:"createdAt"`
 	Signature   string `json:"signature"`
+	// Kid names which issuer public key signed this token, supporting
+	// key rotation. Empty means IssuerPubKey.
+	Kid string `json:"kid,omitempty"`
 }
 
 // ParsedToken contains the raw token and parsed metadata.
@@ -31,6 +62,9 @@ type ParsedToken struct {
 
 // ParseToken decodes and validates a service token.
 // Returns an error if the token format is invalid.
+//
+// ParseToken does not itself verify the token's signature; call
+// VerifyToken once the token is parsed.
 func ParseToken(token string) (*ParsedToken, error) {
 	if !strings.HasPrefix(token, "csc_") {
 		return nil, errors.New("token must start with 'csc_'")
@@ -87,3 +121,120 @@ func ParseToken(token string) (*ParsedToken, error) {
 		TunnelURL:   "wss://market.cascade.fyi/tunnel/connect",
 	}, nil
 }
+
+// VerifyToken checks pt.Payload.Signature against the issuer public key
+// named by pt.Payload.Kid (or IssuerPubKey if unset). The signed message
+// is the SHA-256 hash of the payload's fields, canonicalized as
+// sorted-key JSON with Signature itself excluded. Returns ErrBadSignature
+// if no signature verifies.
+func VerifyToken(pt *ParsedToken) error {
+	pubKey, err := resolveIssuerKey(pt.Payload.Kid)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(pt.Payload.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature: %v", ErrBadSignature, err)
+	}
+
+	canonical, err := canonicalPayload(pt.Payload)
+	if err != nil {
+		return fmt.Errorf("canonicalizing token payload: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+
+	if !ed25519.Verify(pubKey, sum[:], sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// canonicalPayload produces the sorted-key JSON that was signed: every
+// ServiceToken field except Signature. encoding/json sorts map keys, so
+// building the fields as a map is enough to canonicalize them.
+func canonicalPayload(p ServiceToken) ([]byte, error) {
+	fields := map[string]any{
+		"serviceId":   p.ServiceID,
+		"splitConfig": p.SplitConfig,
+		"splitVault":  p.SplitVault,
+		"price":       strconv.FormatInt(p.Price, 10),
+		"createdAt":   p.CreatedAt,
+	}
+	if p.Kid != "" {
+		fields["kid"] = p.Kid
+	}
+	return json.Marshal(fields)
+}
+
+var (
+	issuerKeysOnce sync.Once
+	issuerKeys     map[string]ed25519.PublicKey
+	issuerKeysErr  error
+)
+
+// resolveIssuerKey returns the public key for kid ("" meaning
+// IssuerPubKey), loading and parsing the configured keys on first use.
+func resolveIssuerKey(kid string) (ed25519.PublicKey, error) {
+	issuerKeysOnce.Do(loadIssuerKeys)
+	if issuerKeysErr != nil {
+		return nil, issuerKeysErr
+	}
+
+	key, ok := issuerKeys[kid]
+	if !ok {
+		if kid == "" {
+			return nil, fmt.Errorf("%w: no issuer public key configured", ErrBadSignature)
+		}
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrBadSignature, kid)
+	}
+	return key, nil
+}
+
+func loadIssuerKeys() {
+	issuerKeys = make(map[string]ed25519.PublicKey)
+
+	primary := IssuerPubKey
+	if env := os.Getenv("CASCADE_ISSUER_PUBKEY"); env != "" {
+		primary = env
+	}
+	if primary != "" {
+		key, err := decodeIssuerPubKey(primary)
+		if err != nil {
+			issuerKeysErr = fmt.Errorf("invalid issuer public key: %w", err)
+			return
+		}
+		issuerKeys[""] = key
+	}
+
+	for _, entry := range strings.Split(IssuerPubKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, hexKey, ok := strings.Cut(entry, ":")
+		if !ok {
+			issuerKeysErr = fmt.Errorf("invalid issuer public key entry %q (expected kid:hexkey)", entry)
+			return
+		}
+
+		key, err := decodeIssuerPubKey(hexKey)
+		if err != nil {
+			issuerKeysErr = fmt.Errorf("invalid issuer public key for kid %q: %w", kid, err)
+			return
+		}
+		issuerKeys[kid] = key
+	}
+}
+
+func decodeIssuerPubKey(hexKey string) (ed25519.PublicKey, error) {
+	raw, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
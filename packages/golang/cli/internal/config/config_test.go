@@ -0,0 +1,153 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// resetIssuerKeys clears the memoized issuer key state so each test can
+// set its own IssuerPubKey/IssuerPubKeys and have loadIssuerKeys pick
+// them up, despite resolveIssuerKey only ever running loadIssuerKeys
+// once per process via sync.Once.
+func resetIssuerKeys(t *testing.T) {
+	t.Cleanup(func() {
+		issuerKeysOnce = sync.Once{}
+		issuerKeys = nil
+		issuerKeysErr = nil
+		IssuerPubKey = ""
+		IssuerPubKeys = ""
+	})
+	issuerKeysOnce = sync.Once{}
+	issuerKeys = nil
+	issuerKeysErr = nil
+}
+
+// signedToken signs payload with priv and returns a ParsedToken ready
+// for VerifyToken, mirroring the canonicalization VerifyToken itself
+// performs.
+func signedToken(t *testing.T, priv ed25519.PrivateKey, payload ServiceToken) *ParsedToken {
+	canonical, err := canonicalPayload(payload)
+	if err != nil {
+		t.Fatalf("canonicalPayload: %v", err)
+	}
+	sum := sha256.Sum256(canonical)
+	payload.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sum[:]))
+	return &ParsedToken{Payload: payload}
+}
+
+func TestVerifyToken_RoundTrip(t *testing.T) {
+	resetIssuerKeys(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	IssuerPubKey = hex.EncodeToString(pub)
+
+	pt := signedToken(t, priv, ServiceToken{
+		ServiceID:   "svc-123",
+		SplitConfig: "config-abc",
+		SplitVault:  "vault-abc",
+		Price:       1000,
+		CreatedAt:   1700000000,
+	})
+
+	if err := VerifyToken(pt); err != nil {
+		t.Fatalf("VerifyToken() = %v, want nil", err)
+	}
+}
+
+func TestVerifyToken_TamperedFieldFailsClosed(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	base := ServiceToken{
+		ServiceID:   "svc-123",
+		SplitConfig: "config-abc",
+		SplitVault:  "vault-abc",
+		Price:       1000,
+		CreatedAt:   1700000000,
+	}
+
+	tests := []struct {
+		name  string
+		alter func(pt *ParsedToken)
+	}{
+		{"price", func(pt *ParsedToken) { pt.Payload.Price = 999999 }},
+		{"splitConfig", func(pt *ParsedToken) { pt.Payload.SplitConfig = "config-xyz" }},
+		{"kid", func(pt *ParsedToken) { pt.Payload.Kid = "other-kid" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetIssuerKeys(t)
+			IssuerPubKey = hex.EncodeToString(pub)
+
+			pt := signedToken(t, priv, base)
+			tt.alter(pt)
+
+			err := VerifyToken(pt)
+			if !errors.Is(err, ErrBadSignature) {
+				t.Fatalf("VerifyToken() = %v, want ErrBadSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyToken_NoIssuerKeyFailsClosed(t *testing.T) {
+	resetIssuerKeys(t)
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	pt := signedToken(t, priv, ServiceToken{
+		ServiceID:   "svc-123",
+		SplitConfig: "config-abc",
+		SplitVault:  "vault-abc",
+		Price:       1000,
+		CreatedAt:   1700000000,
+	})
+
+	if err := VerifyToken(pt); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("VerifyToken() = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestVerifyToken_KidRotation(t *testing.T) {
+	resetIssuerKeys(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	IssuerPubKeys = "rotated:" + hex.EncodeToString(pub)
+
+	pt := signedToken(t, priv, ServiceToken{
+		ServiceID:   "svc-123",
+		SplitConfig: "config-abc",
+		SplitVault:  "vault-abc",
+		Price:       1000,
+		CreatedAt:   1700000000,
+		Kid:         "rotated",
+	})
+
+	if err := VerifyToken(pt); err != nil {
+		t.Fatalf("VerifyToken() = %v, want nil", err)
+	}
+
+	// An unknown kid must not fall back to any configured key.
+	pt.Payload.Kid = "unknown"
+	if err := VerifyToken(pt); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("VerifyToken() with unknown kid = %v, want ErrBadSignature", err)
+	}
+}
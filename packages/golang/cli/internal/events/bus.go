@@ -0,0 +1,101 @@
+// Package events implements a small in-process publish/subscribe bus so
+// the tunnel client can report its connection and request lifecycle
+// without knowing who, if anyone, is listening. The colored stdout
+// logger, the admin HTTP API, and any future exporter all subscribe
+// independently.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind identifies the category of an Event.
+type Kind int
+
+const (
+	// Connected reports a successful tunnel connection.
+	Connected Kind = iota
+	// Disconnected reports the tunnel connection dropping.
+	Disconnected
+	// Reconnecting reports a scheduled reconnect attempt after a drop.
+	Reconnecting
+	// Error reports an error, such as a failed frame write or a response
+	// body that could not be fully relayed. It carries only Err, with no
+	// request fields, even when it arose while serving a request.
+	Error
+	// Request reports a tunnel request arriving for the local server.
+	Request
+	// Response reports the response sent back for a Request.
+	Response
+)
+
+// Event is a single thing that happened in the tunnel client. Only the
+// fields relevant to Kind are populated.
+type Event struct {
+	Kind Kind
+	At   time.Time
+
+	// Disconnected
+	Code   int
+	Reason string
+
+	// Reconnecting
+	Attempt int
+	Delay   time.Duration
+
+	// Error
+	Err error
+
+	// Request / Response
+	RequestID string
+	Method    string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	BytesIn   int
+	BytesOut  int
+}
+
+// Bus fans Events out to any number of subscribers.
+type Bus struct {
+	mu   sync.RWMutex
+	subs []chan Event
+}
+
+// NewBus creates an empty event bus.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe returns a channel that receives every Event published after
+// this call. The channel is buffered; a subscriber that falls behind
+// drops events rather than stalling the tunnel.
+func (b *Bus) Subscribe() <-chan Event {
+	ch := make(chan Event, 64)
+
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+
+	return ch
+}
+
+// Publish fans e out to every current subscriber without blocking.
+func (b *Bus) Publish(e Event) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			// Subscriber's buffer is full; drop rather than block the
+			// tunnel on a slow consumer.
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package router
+
+// ActionKind identifies what a matched Rule tells the Router to do.
+type ActionKind int
+
+const (
+	// ActionAllow forwards the request to the local MCP server unchanged.
+	ActionAllow ActionKind = iota
+	// ActionDeny rejects the request before it reaches the local server.
+	ActionDeny
+	// ActionRewritePath forwards the request with its path replaced.
+	ActionRewritePath
+	// ActionRouteToUpstream forwards the request to a named entry in
+	// tunnel.Options.Upstreams instead of the default local server.
+	ActionRouteToUpstream
+)
+
+// Action is what a matched Rule tells the Router to do with a Request.
+type Action struct {
+	Kind ActionKind
+	// Path is the replacement path, set when Kind is ActionRewritePath.
+	Path string
+	// Upstream names the tunnel.Options.Upstreams entry to use, set when
+	// Kind is ActionRouteToUpstream.
+	Upstream string
+}
+
+// Allow forwards the request unchanged.
+func Allow() Action { return Action{Kind: ActionAllow} }
+
+// Deny rejects the request before it reaches the local server.
+func Deny() Action { return Action{Kind: ActionDeny} }
+
+// RewritePath forwards the request with its path replaced by newPath.
+func RewritePath(newPath string) Action {
+	return Action{Kind: ActionRewritePath, Path: newPath}
+}
+
+// RouteToUpstream forwards the request to the named upstream instead of
+// the default local server.
+func RouteToUpstream(name string) Action {
+	return Action{Kind: ActionRouteToUpstream, Upstream: name}
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleDoc is the on-disk representation of a single rule, as loaded from
+// the --rules file. Exactly one of Method, PathGlob, Header, or
+// ClientID must be set.
+type ruleDoc struct {
+	Method      string `json:"method,omitempty" yaml:"method,omitempty"`
+	PathGlob    string `json:"pathGlob,omitempty" yaml:"pathGlob,omitempty"`
+	Header      string `json:"header,omitempty" yaml:"header,omitempty"`
+	HeaderValue string `json:"headerValue,omitempty" yaml:"headerValue,omitempty"`
+	ClientID    string `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+
+	Action      string `json:"action" yaml:"action"`
+	RewritePath string `json:"rewritePath,omitempty" yaml:"rewritePath,omitempty"`
+	Upstream    string `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+}
+
+type rulesDoc struct {
+	Rules []ruleDoc `json:"rules" yaml:"rules"`
+}
+
+// LoadRulesFile reads a YAML or JSON rules file (selected by its file
+// extension; anything other than ".json" is parsed as YAML) and builds
+// the Rule list it describes, in file order.
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+
+	var doc rulesDoc
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing rules file: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(doc.Rules))
+	for i, d := range doc.Rules {
+		act, err := parseAction(d)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		set := 0
+		for _, v := range []string{d.Method, d.PathGlob, d.Header, d.ClientID} {
+			if v != "" {
+				set++
+			}
+		}
+		if set > 1 {
+			return nil, fmt.Errorf("rule %d: must set only one of method, pathGlob, header, or clientId", i)
+		}
+
+		switch {
+		case d.Method != "":
+			rules = append(rules, NewMethodRule(d.Method, act))
+		case d.PathGlob != "":
+			rules = append(rules, NewPathGlobRule(d.PathGlob, act))
+		case d.Header != "":
+			rules = append(rules, NewHeaderRule(d.Header, d.HeaderValue, act))
+		case d.ClientID != "":
+			rules = append(rules, NewClientRule(d.ClientID, act))
+		default:
+			return nil, fmt.Errorf("rule %d: must set one of method, pathGlob, header, or clientId", i)
+		}
+	}
+	return rules, nil
+}
+
+func parseAction(d ruleDoc) (Action, error) {
+	switch d.Action {
+	case "allow":
+		return Allow(), nil
+	case "deny":
+		return Deny(), nil
+	case "rewrite_path":
+		if d.RewritePath == "" {
+			return Action{}, fmt.Errorf("rewrite_path action requires rewritePath")
+		}
+		return RewritePath(d.RewritePath), nil
+	case "route_to_upstream":
+		if d.Upstream == "" {
+			return Action{}, fmt.Errorf("route_to_upstream action requires upstream")
+		}
+		return RouteToUpstream(d.Upstream), nil
+	default:
+		return Action{}, fmt.Errorf("unknown action %q", d.Action)
+	}
+}
@@ -0,0 +1,28 @@
+package router
+
+import "net/http"
+
+// Request is the subset of an incoming tunnel request a Rule can match
+// against. It mirrors tunnel.Request but lives in this package to avoid
+// an import cycle, since tunnel.Client depends on Router.
+type Request struct {
+	Method  string
+	Path    string
+	Headers map[string]string
+	// ClientID identifies the remote tunnel client, extracted from the
+	// X-Client-Id tunnel header.
+	ClientID string
+}
+
+// HeaderValue looks up name in headers case-insensitively, since HTTP
+// header names are and the tunnel relay's map casing isn't guaranteed.
+// Returns ok=false if no matching key is present.
+func HeaderValue(headers map[string]string, name string) (string, bool) {
+	canon := http.CanonicalHeaderKey(name)
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == canon {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,47 @@
+// Package router implements rule-based request routing and access
+// control between the tunnel relay and the local MCP server(s) a client
+// fronts, loosely inspired by sing-box's Router/Rule split.
+package router
+
+import "sync"
+
+// Router evaluates an ordered set of Rules against incoming Requests and
+// resolves each one to an Action.
+type Router interface {
+	// Route returns the Action of the first matching rule, or Allow()
+	// when no rule matches.
+	Route(req *Request) Action
+	// SetRules atomically replaces the active rule set, used to apply a
+	// reloaded --rules file without dropping the tunnel connection.
+	SetRules(rules []Rule)
+}
+
+// staticRouter is the default Router: an in-memory, hot-swappable rule
+// list evaluated in order.
+type staticRouter struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// New creates a Router starting with the given rules.
+func New(rules ...Rule) Router {
+	return &staticRouter{rules: rules}
+}
+
+func (r *staticRouter) Route(req *Request) Action {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rule := range r.rules {
+		if rule.Match(req) {
+			return rule.Action()
+		}
+	}
+	return Allow()
+}
+
+func (r *staticRouter) SetRules(rules []Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = rules
+}
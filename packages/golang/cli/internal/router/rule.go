@@ -0,0 +1,95 @@
+package router
+
+import (
+	"path"
+	"strings"
+)
+
+// Rule decides, for a single incoming Request, what Action the Router
+// should take. A Router evaluates rules in order and applies the first
+// match.
+type Rule interface {
+	Match(req *Request) bool
+	Action() Action
+}
+
+// MethodRule matches requests by HTTP method (case-insensitive).
+type MethodRule struct {
+	Method string
+	act    Action
+}
+
+// NewMethodRule returns a Rule that applies act to requests using method.
+func NewMethodRule(method string, act Action) *MethodRule {
+	return &MethodRule{Method: method, act: act}
+}
+
+func (r *MethodRule) Match(req *Request) bool {
+	return strings.EqualFold(req.Method, r.Method)
+}
+
+func (r *MethodRule) Action() Action { return r.act }
+
+// PathGlobRule matches requests whose path satisfies a path.Match glob,
+// e.g. "/admin/*".
+type PathGlobRule struct {
+	Glob string
+	act  Action
+}
+
+// NewPathGlobRule returns a Rule that applies act to requests whose path
+// matches glob.
+func NewPathGlobRule(glob string, act Action) *PathGlobRule {
+	return &PathGlobRule{Glob: glob, act: act}
+}
+
+func (r *PathGlobRule) Match(req *Request) bool {
+	ok, err := path.Match(r.Glob, req.Path)
+	return err == nil && ok
+}
+
+func (r *PathGlobRule) Action() Action { return r.act }
+
+// HeaderRule matches requests carrying Header. If Value is empty, the
+// header's mere presence is enough; otherwise its value must equal
+// Value.
+type HeaderRule struct {
+	Header string
+	Value  string
+	act    Action
+}
+
+// NewHeaderRule returns a Rule that applies act to requests carrying
+// header (optionally matching a specific value).
+func NewHeaderRule(header, value string, act Action) *HeaderRule {
+	return &HeaderRule{Header: header, Value: value, act: act}
+}
+
+func (r *HeaderRule) Match(req *Request) bool {
+	v, ok := HeaderValue(req.Headers, r.Header)
+	if !ok {
+		return false
+	}
+	return r.Value == "" || v == r.Value
+}
+
+func (r *HeaderRule) Action() Action { return r.act }
+
+// ClientRule matches requests from a specific remote client identity, as
+// extracted from the tunnel's X-Client-Id header.
+type ClientRule struct {
+	ClientID string
+	act      Action
+}
+
+// NewClientRule returns a Rule that applies act to requests from
+// clientID.
+func NewClientRule(clientID string, act Action) *ClientRule {
+	return &ClientRule{ClientID: clientID, act: act}
+}
+
+func (r *ClientRule) Match(req *Request) bool {
+	return req.ClientID == r.ClientID
+}
+
+func (r *ClientRule) Action() Action { return r.act }
@@ -0,0 +1,205 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// ALPN is the application protocol negotiated for the QUIC tunnel
+// transport, distinguishing it from plain HTTP/3 traffic on the wire.
+const ALPN = "csc-tunnel/1"
+
+// quicKeepAlive matches the WebSocket ping loop's 30s cadence so idle
+// connections are detected on roughly the same schedule regardless of
+// which transport is in use.
+const quicKeepAlive = 30 * time.Second
+
+// QUICTransport relays tunnel frames over a single bidirectional QUIC
+// stream. Compared to WebSocketTransport it survives network changes
+// (Wi-Fi/LTE handoff) without a reconnect. All requests share the one
+// stream, so a stalled frame still delays the frames queued behind it.
+//
+// Because a QUIC stream is a raw byte stream rather than a message
+// transport like WebSocket, messages are framed with a 4-byte big-endian
+// length prefix. The handshake headers (notably X-SERVICE-TOKEN) are sent
+// as the first frame.
+type QUICTransport struct {
+	conn   *quic.Conn
+	stream *quic.Stream
+	reader *bufio.Reader
+
+	// writeMu serializes the length-prefix + payload writes in writeFrame.
+	// Unlike WebSocketTransport, whose underlying library serializes
+	// writes itself, a QUIC stream is a raw byte stream: concurrent
+	// callers (one per in-flight request, plus inline acks from
+	// messageLoop) would otherwise interleave their length prefixes and
+	// payloads and desync the peer's framing.
+	writeMu sync.Mutex
+}
+
+// NewQUICTransport creates an unconnected QUIC transport.
+func NewQUICTransport() *QUICTransport {
+	return &QUICTransport{}
+}
+
+// Dial opens a QUIC connection to rawURL (parsed for its host and port,
+// defaulting to 443; the scheme and path are ignored), negotiates the ALPN, opens a single bidirectional
+// stream, and sends headers as the first framed message.
+func (t *QUICTransport) Dial(ctx context.Context, rawURL string, headers http.Header) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid tunnel URL: %w", err)
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	tlsConf := &tls.Config{
+		ServerName: host,
+		NextProtos: []string{ALPN},
+	}
+
+	conn, err := quic.DialAddr(ctx, net.JoinHostPort(host, port), tlsConf, &quic.Config{
+		KeepAlivePeriod: quicKeepAlive,
+	})
+	if err != nil {
+		return fmt.Errorf("quic dial failed: %w", err)
+	}
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(0, "stream open failed")
+		return fmt.Errorf("quic stream open failed: %w", err)
+	}
+
+	flatHeaders := make(map[string]string, len(headers))
+	for k := range headers {
+		flatHeaders[k] = headers.Get(k)
+	}
+	headerFrame, err := json.Marshal(flatHeaders)
+	if err != nil {
+		conn.CloseWithError(0, "handshake failed")
+		return fmt.Errorf("encoding handshake headers: %w", err)
+	}
+	if err := writeFrame(stream, headerFrame); err != nil {
+		conn.CloseWithError(0, "handshake failed")
+		return fmt.Errorf("quic handshake failed: %w", err)
+	}
+
+	t.conn = conn
+	t.stream = stream
+	t.reader = bufio.NewReader(stream)
+	return nil
+}
+
+// Read returns the next length-prefixed frame from the stream. Honors
+// ctx cancellation, unlike a bare quic.Stream.Read: cancelling ctx
+// unblocks it the same way closing the connection would for
+// WebSocketTransport.
+func (t *QUICTransport) Read(ctx context.Context) ([]byte, error) {
+	stop := t.watchCancel(ctx)
+	defer stop()
+
+	data, err := readFrame(t.reader)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return data, err
+}
+
+// Write sends data as a single length-prefixed frame. Safe for
+// concurrent use: it serializes the length-prefix and payload writes so
+// concurrent callers can't interleave their frames on the wire. Also
+// honors ctx cancellation; see Read.
+func (t *QUICTransport) Write(ctx context.Context, data []byte) error {
+	stop := t.watchCancel(ctx)
+	defer stop()
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if err := writeFrame(t.stream, data); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// watchCancel arranges for t.stream's read/write deadline to expire as
+// soon as ctx is done, unblocking whichever of Read or Write is
+// in-flight, and returns a func to stop watching once the call
+// returns.
+func (t *QUICTransport) watchCancel(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.stream.SetDeadline(time.Unix(0, 0))
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Ping reports whether the QUIC connection is still alive. QUIC's own
+// PATH_CHALLENGE/keep-alive machinery (quicKeepAlive) detects dead peers,
+// so this only needs to surface a connection the peer has already closed.
+func (t *QUICTransport) Ping(ctx context.Context) error {
+	select {
+	case <-t.conn.Context().Done():
+		return t.conn.Context().Err()
+	default:
+		return nil
+	}
+}
+
+// Close tears down the stream and the underlying QUIC connection.
+func (t *QUICTransport) Close() error {
+	if t.stream != nil {
+		t.stream.Close()
+	}
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.CloseWithError(0, "client closing")
+}
+
+func writeFrame(w io.Writer, data []byte) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(lenBuf[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
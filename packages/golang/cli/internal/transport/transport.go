@@ -0,0 +1,27 @@
+// Package transport provides pluggable connections for the tunnel client,
+// so the wire protocol used to reach the relay (WebSocket or QUIC) can be
+// swapped without changing how the client frames requests and responses.
+package transport
+
+import (
+	"context"
+	"net/http"
+)
+
+// Transport is a bidirectional, message-framed connection to the tunnel
+// relay. Implementations own establishing the underlying connection and
+// framing individual messages on the wire; callers only deal in whole
+// messages.
+type Transport interface {
+	// Dial establishes the connection to rawURL, sending headers as part
+	// of the handshake.
+	Dial(ctx context.Context, rawURL string, headers http.Header) error
+	// Read blocks until a full message is available and returns it.
+	Read(ctx context.Context) ([]byte, error)
+	// Write sends a single message.
+	Write(ctx context.Context, data []byte) error
+	// Ping checks that the connection is still alive.
+	Ping(ctx context.Context) error
+	// Close tears down the connection.
+	Close() error
+}
@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coder/websocket"
+)
+
+// WebSocketTransport is the default Transport, backed by a
+// github.com/coder/websocket connection. It is what the client has always
+// used to reach the tunnel relay.
+type WebSocketTransport struct {
+	conn *websocket.Conn
+}
+
+// NewWebSocketTransport creates an unconnected WebSocket transport.
+func NewWebSocketTransport() *WebSocketTransport {
+	return &WebSocketTransport{}
+}
+
+// Dial opens the WebSocket connection to rawURL, sending headers as the
+// handshake's HTTP headers.
+func (t *WebSocketTransport) Dial(ctx context.Context, rawURL string, headers http.Header) error {
+	conn, _, err := websocket.Dial(ctx, rawURL, &websocket.DialOptions{
+		HTTPHeader: headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	t.conn = conn
+	return nil
+}
+
+// Read returns the next WebSocket message.
+func (t *WebSocketTransport) Read(ctx context.Context) ([]byte, error) {
+	_, data, err := t.conn.Read(ctx)
+	return data, err
+}
+
+// Write sends data as a single WebSocket text message.
+func (t *WebSocketTransport) Write(ctx context.Context, data []byte) error {
+	return t.conn.Write(ctx, websocket.MessageText, data)
+}
+
+// Ping sends a WebSocket ping frame and waits for the pong.
+func (t *WebSocketTransport) Ping(ctx context.Context) error {
+	return t.conn.Ping(ctx)
+}
+
+// Close closes the WebSocket connection with a normal closure status.
+func (t *WebSocketTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close(websocket.StatusNormalClosure, "client closing")
+}
@@ -0,0 +1,69 @@
+package tunnel
+
+import (
+	"context"
+	"sync"
+)
+
+// ackTracker lets a sender block until the peer has acked up through a
+// given sequence number, implementing the chunk-window backpressure: a
+// sender waits here before putting more than maxInFlightChunks
+// unacknowledged chunks on the wire. It also wakes waitUntil if ctx,
+// the owning connection's context, is cancelled, so a sender isn't
+// parked forever waiting for acks a dropped connection will never
+// deliver.
+type ackTracker struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	acked     int
+	cancelled bool
+	done      chan struct{}
+}
+
+func newAckTracker(ctx context.Context) *ackTracker {
+	t := &ackTracker{done: make(chan struct{})}
+	t.cond = sync.NewCond(&t.mu)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.mu.Lock()
+			t.cancelled = true
+			t.mu.Unlock()
+			t.cond.Broadcast()
+		case <-t.done:
+		}
+	}()
+
+	return t
+}
+
+// ack records that the peer has consumed every chunk up through seq.
+func (t *ackTracker) ack(seq int) {
+	t.mu.Lock()
+	if seq > t.acked {
+		t.acked = seq
+		t.cond.Broadcast()
+	}
+	t.mu.Unlock()
+}
+
+// close stops watching the owning connection's context once the
+// tracker is no longer needed.
+func (t *ackTracker) close() {
+	select {
+	case <-t.done:
+	default:
+		close(t.done)
+	}
+}
+
+// waitUntil blocks until the peer has acked at least seq, or ctx (the
+// owning connection's context, passed to newAckTracker) is cancelled.
+func (t *ackTracker) waitUntil(seq int) {
+	t.mu.Lock()
+	for t.acked < seq && !t.cancelled {
+		t.cond.Wait()
+	}
+	t.mu.Unlock()
+}
@@ -1,76 +1,95 @@
-// Package tunnel provides a WebSocket client that connects to the
-// TunnelRelay Durable Object and forwards MCP requests to a local server.
+// Package tunnel provides a client that connects to the TunnelRelay
+// Durable Object and forwards MCP requests to a local server. The
+// connection itself is pluggable via transport.Transport (WebSocket by
+// default, QUIC optionally). Request and response bodies are streamed
+// across the connection as chunked frames rather than buffered in
+// memory; see frame.go.
 package tunnel
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"sync"
 	"time"
 
 	"github.com/cascade-protocol/splits/cli/internal/config"
-	"github.com/coder/websocket"
+	"github.com/cascade-protocol/splits/cli/internal/events"
+	"github.com/cascade-protocol/splits/cli/internal/router"
+	"github.com/cascade-protocol/splits/cli/internal/transport"
 	"github.com/fatih/color"
 )
 
-// Request represents an incoming tunnel request.
-type Request struct {
-	Type    string            `json:"type"`
-	ID      string            `json:"id"`
-	Method  string            `json:"method"`
-	Path    string            `json:"path"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
-}
-
-// Response represents an outgoing tunnel response.
-type Response struct {
-	Type    string            `json:"type"`
-	ID      string            `json:"id"`
-	Status  int               `json:"status"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
-}
-
 // Options configures the tunnel client.
 type Options struct {
-	Token        *config.ParsedToken
-	LocalURL     string
-	Debug        bool
-	OnConnect    func()
-	OnDisconnect func(code int, reason string)
-	OnError      func(err error)
-	OnRequest    func(method, path string)
+	Token    *config.ParsedToken
+	LocalURL string
+	Debug    bool
+	// Transport selects the connection used to reach the tunnel relay.
+	// Defaults to transport.NewWebSocketTransport() when nil. If set to a
+	// *transport.QUICTransport and the initial dial fails, the client
+	// falls back to WebSocket automatically.
+	Transport transport.Transport
+	// Router, if set, is consulted for every incoming request before it
+	// is dispatched to the local MCP server. Denied requests never reach
+	// httpClient; RouteToUpstream requests are sent to Upstreams instead.
+	Router    router.Router
+	Upstreams map[string]string
+	// Events, if set, receives the client's connection and request
+	// lifecycle. If nil, NewClient creates one, retrievable via
+	// Client.Events.
+	Events *events.Bus
 }
 
-// Client manages the WebSocket connection to the tunnel relay.
+// Client manages the connection to the tunnel relay.
 type Client struct {
 	opts              Options
-	conn              *websocket.Conn
+	events            *events.Bus
+	transport         transport.Transport
 	mu                sync.Mutex
 	reconnectAttempts int
 	maxReconnects     int
 	reconnectDelay    time.Duration
 	httpClient        *http.Client
+
+	// inflight tracks requests whose body is still streaming in as
+	// request_chunk frames, keyed by request ID.
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightRequest
+
+	// responseAcks tracks the ack windows for responses this client is
+	// currently streaming out, keyed by request ID.
+	ackMu        sync.Mutex
+	responseAcks map[string]*ackTracker
 }
 
 // NewClient creates a new tunnel client.
 func NewClient(opts Options) *Client {
+	bus := opts.Events
+	if bus == nil {
+		bus = events.NewBus()
+	}
+
 	return &Client{
 		opts:           opts,
+		events:         bus,
 		maxReconnects:  10,
 		reconnectDelay: time.Second,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		inflight:     make(map[string]*inflightRequest),
+		responseAcks: make(map[string]*ackTracker),
 	}
 }
 
+// Events returns the bus the client publishes its connection and request
+// lifecycle to. Subscribe to observe it without the client knowing.
+func (c *Client) Events() *events.Bus {
+	return c.events
+}
+
 // Connect establishes and maintains the WebSocket connection.
 func (c *Client) Connect(ctx context.Context) error {
 	return c.connectWithRetry(ctx)
@@ -81,9 +100,7 @@ func (c *Client) connectWithRetry(ctx context.Context) error {
 		err := c.dial(ctx)
 		if err == nil {
 			c.reconnectAttempts = 0
-			if c.opts.OnConnect != nil {
-				c.opts.OnConnect()
-			}
+			c.events.Publish(events.Event{Kind: events.Connected})
 
 			// Start message loop
 			err = c.messageLoop(ctx)
@@ -95,9 +112,7 @@ func (c *Client) connectWithRetry(ctx context.Context) error {
 		}
 
 		// Handle disconnection
-		if c.opts.OnDisconnect != nil {
-			c.opts.OnDisconnect(1006, err.Error())
-		}
+		c.events.Publish(events.Event{Kind: events.Disconnected, Code: 1006, Reason: err.Error()})
 
 		// Check if we should retry
 		c.reconnectAttempts++
@@ -111,6 +126,7 @@ func (c *Client) connectWithRetry(ctx context.Context) error {
 			delay = 30 * time.Second
 		}
 
+		c.events.Publish(events.Event{Kind: events.Reconnecting, Attempt: c.reconnectAttempts, Delay: delay})
 		color.Yellow("Reconnecting in %v (attempt %d/%d)...",
 			delay, c.reconnectAttempts, c.maxReconnects)
 
@@ -131,21 +147,45 @@ func (c *Client) dial(ctx context.Context) error {
 	headers := http.Header{}
 	headers.Set("X-SERVICE-TOKEN", c.opts.Token.Raw)
 
-	conn, _, err := websocket.Dial(ctx, c.opts.Token.TunnelURL, &websocket.DialOptions{
-		HTTPHeader: headers,
-	})
+	t := c.opts.Transport
+	if t == nil {
+		t = transport.NewWebSocketTransport()
+	}
+
+	err := t.Dial(ctx, c.opts.Token.TunnelURL, headers)
 	if err != nil {
-		return fmt.Errorf("dial failed: %w", err)
+		if _, isQUIC := t.(*transport.QUICTransport); !isQUIC {
+			return fmt.Errorf("dial failed: %w", err)
+		}
+
+		if c.opts.Debug {
+			color.Yellow("QUIC dial failed, falling back to WebSocket: %v", err)
+		}
+		t = transport.NewWebSocketTransport()
+		if err := t.Dial(ctx, c.opts.Token.TunnelURL, headers); err != nil {
+			return fmt.Errorf("dial failed: %w", err)
+		}
 	}
 
 	c.mu.Lock()
-	c.conn = conn
+	c.transport = t
 	c.mu.Unlock()
 
 	return nil
 }
 
 func (c *Client) messageLoop(ctx context.Context) error {
+	// connCtx scopes every request this connection starts. Cancelling
+	// it when this connection ends — even on a dirty disconnect, not
+	// just a clean shutdown — wakes any drainRequestBody/ackTracker
+	// still parked waiting on frames or acks this dead connection will
+	// never deliver, and aborts their in-flight httpClient.Do/resp.Body
+	// reads, instead of leaking a goroutine and a local-server
+	// connection per in-flight request on every reconnect.
+	connCtx, cancelConn := context.WithCancel(ctx)
+	defer cancelConn()
+	defer c.clearInflight()
+
 	// Start ping loop
 	pingCtx, cancelPing := context.WithCancel(ctx)
 	defer cancelPing()
@@ -153,25 +193,56 @@ func (c *Client) messageLoop(ctx context.Context) error {
 	go c.pingLoop(pingCtx)
 
 	for {
-		_, data, err := c.conn.Read(ctx)
+		data, err := c.transport.Read(ctx)
 		if err != nil {
 			return err
 		}
 
-		var req Request
-		if err := json.Unmarshal(data, &req); err != nil {
+		var f Frame
+		if err := json.Unmarshal(data, &f); err != nil {
 			if c.opts.Debug {
 				color.Red("Error parsing message: %v", err)
 			}
 			continue
 		}
 
-		if req.Type == "request" {
-			go c.handleRequest(ctx, req)
+		switch f.Type {
+		case frameRequestStart:
+			// Inline so the request is registered before its first
+			// request_chunk is read; handleRequestStart only starts
+			// goroutines and never blocks.
+			c.handleRequestStart(connCtx, f)
+		case frameRequestChunk:
+			// Runs inline, not in a goroutine, to keep chunks for a
+			// given request in arrival order; handleRequestChunk only
+			// appends to the request's queue and never blocks, so a
+			// slow local server can't stall this loop for other
+			// multiplexed requests. See drainRequestBody.
+			c.handleRequestChunk(f)
+		case frameRequestEnd:
+			c.handleRequestEnd(f)
+		case frameResponseAck:
+			c.handleResponseAck(f)
 		}
 	}
 }
 
+// clearInflight drops every inflight request and response-ack tracker
+// once the connection that owns them ends, so a request that was
+// mid-stream when the connection dropped doesn't linger in these maps
+// across a reconnect. The goroutines that held the old entries wind
+// down on their own once connCtx cancels; this just stops messageLoop's
+// next connection from inheriting stale bookkeeping.
+func (c *Client) clearInflight() {
+	c.inflightMu.Lock()
+	c.inflight = make(map[string]*inflightRequest)
+	c.inflightMu.Unlock()
+
+	c.ackMu.Lock()
+	c.responseAcks = make(map[string]*ackTracker)
+	c.ackMu.Unlock()
+}
+
 func (c *Client) pingLoop(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -182,11 +253,11 @@ func (c *Client) pingLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			c.mu.Lock()
-			conn := c.conn
+			t := c.transport
 			c.mu.Unlock()
 
-			if conn != nil {
-				if err := conn.Ping(ctx); err != nil {
+			if t != nil {
+				if err := t.Ping(ctx); err != nil {
 					if c.opts.Debug {
 						color.Red("Ping failed: %v", err)
 					}
@@ -196,121 +267,13 @@ func (c *Client) pingLoop(ctx context.Context) {
 	}
 }
 
-func (c *Client) handleRequest(ctx context.Context, req Request) {
-	if c.opts.Debug {
-		color.Blue("← %s %s", req.Method, req.Path)
-	}
-
-	if c.opts.OnRequest != nil {
-		c.opts.OnRequest(req.Method, req.Path)
-	}
-
-	// Forward to local MCP server
-	localURL, err := url.JoinPath(c.opts.LocalURL, req.Path)
-	if err != nil {
-		c.sendErrorResponse(ctx, req.ID, 502, "Invalid path")
-		return
-	}
-
-	var body io.Reader
-	if req.Method != "GET" && req.Method != "HEAD" && req.Body != "" {
-		body = bytes.NewBufferString(req.Body)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, localURL, body)
-	if err != nil {
-		c.sendErrorResponse(ctx, req.ID, 502, err.Error())
-		return
-	}
-
-	for k, v := range req.Headers {
-		httpReq.Header.Set(k, v)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		if c.opts.Debug {
-			color.Red("Error forwarding request: %v", err)
-		}
-		c.sendErrorResponse(ctx, req.ID, 502, err.Error())
-		return
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		c.sendErrorResponse(ctx, req.ID, 502, err.Error())
-		return
-	}
-
-	respHeaders := make(map[string]string)
-	for k, v := range resp.Header {
-		if len(v) > 0 {
-			respHeaders[k] = v[0]
-		}
-	}
-
-	tunnelResp := Response{
-		Type:    "response",
-		ID:      req.ID,
-		Status:  resp.StatusCode,
-		Headers: respHeaders,
-		Body:    string(respBody),
-	}
-
-	if c.opts.Debug {
-		color.Green("→ %d", resp.StatusCode)
-	}
-
-	c.sendResponse(ctx, tunnelResp)
-}
-
-func (c *Client) sendErrorResponse(ctx context.Context, id string, status int, message string) {
-	errBody, _ := json.Marshal(map[string]string{
-		"error":   "Local server error",
-		"message": message,
-	})
-
-	resp := Response{
-		Type:    "response",
-		ID:      id,
-		Status:  status,
-		Headers: map[string]string{"Content-Type": "application/json"},
-		Body:    string(errBody),
-	}
-
-	c.sendResponse(ctx, resp)
-}
-
-func (c *Client) sendResponse(ctx context.Context, resp Response) {
-	data, err := json.Marshal(resp)
-	if err != nil {
-		if c.opts.OnError != nil {
-			c.opts.OnError(err)
-		}
-		return
-	}
-
-	c.mu.Lock()
-	conn := c.conn
-	c.mu.Unlock()
-
-	if conn != nil {
-		if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
-			if c.opts.OnError != nil {
-				c.opts.OnError(err)
-			}
-		}
-	}
-}
-
-// Close closes the WebSocket connection.
+// Close closes the tunnel transport.
 func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn != nil {
-		return c.conn.Close(websocket.StatusNormalClosure, "client closing")
+	if c.transport != nil {
+		return c.transport.Close()
 	}
 	return nil
 }
@@ -0,0 +1,49 @@
+package tunnel
+
+// Frame types. A request or response body is no longer sent as one JSON
+// string; it's relayed as a start frame carrying metadata, zero or more
+// chunk frames carrying a piece of the body, and an end frame, so bodies
+// of any size can stream through without being buffered in memory.
+const (
+	frameRequestStart  = "request_start"
+	frameRequestChunk  = "request_chunk"
+	frameRequestEnd    = "request_end"
+	frameRequestAck    = "request_ack"
+	frameResponseStart = "response_start"
+	frameResponseChunk = "response_chunk"
+	frameResponseEnd   = "response_end"
+	frameResponseAck   = "response_ack"
+	frameResponseError = "response_error"
+)
+
+// chunkSize is the maximum number of body bytes carried by a single
+// chunk frame.
+const chunkSize = 32 * 1024
+
+// maxInFlightChunks caps how many chunks a sender puts on the wire
+// before the peer has acked them, bounding memory use on a slow peer.
+const maxInFlightChunks = 8
+
+// Frame is the wire envelope for every tunnel message. Only the fields
+// relevant to Type are populated.
+type Frame struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// Seq numbers chunk and ack frames, starting at 1, so the peer can
+	// tell which chunks an ack covers.
+	Seq int `json:"seq,omitempty"`
+
+	// request_start (Headers is also set on response_start)
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// response_start / response_error
+	Status int `json:"status,omitempty"`
+
+	// request_chunk / response_chunk: base64-encoded body bytes.
+	Chunk string `json:"chunk,omitempty"`
+
+	// response_error
+	Error string `json:"error,omitempty"`
+}
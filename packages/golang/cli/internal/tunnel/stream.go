@@ -0,0 +1,385 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cascade-protocol/splits/cli/internal/events"
+	"github.com/cascade-protocol/splits/cli/internal/router"
+	"github.com/fatih/color"
+)
+
+// inflightRequest is the streaming state for one tunnel request whose
+// body is still arriving as request_chunk frames. handleRequestChunk
+// appends to queue as frames arrive off the shared read loop; push
+// never blocks, so a local server that's slow to read the body for one
+// request can't stall delivery of frames for any other multiplexed
+// request. drainRequestBody is the sole consumer, draining queue in
+// order and only acking a chunk back to the peer once it has actually
+// been written to the local server, so peer-side backpressure still
+// reflects real consumption rather than local buffering.
+type inflightRequest struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	queue     []requestChunk
+	ended     bool
+	cancelled bool
+	done      chan struct{}
+}
+
+type requestChunk struct {
+	seq  int
+	data []byte
+}
+
+// newInflightRequest creates streaming state for one request. ctx is
+// the owning connection's context: if it's cancelled before request_end
+// arrives (the connection dropped mid-stream), next wakes and returns
+// ok=false instead of leaving drainRequestBody parked forever waiting
+// for frames a dead connection will never deliver.
+func newInflightRequest(ctx context.Context) *inflightRequest {
+	s := &inflightRequest{done: make(chan struct{})}
+	s.cond = sync.NewCond(&s.mu)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.cancelled = true
+			s.mu.Unlock()
+			s.cond.Broadcast()
+		case <-s.done:
+		}
+	}()
+
+	return s
+}
+
+// push appends chunk to the queue without blocking.
+func (s *inflightRequest) push(chunk requestChunk) {
+	s.mu.Lock()
+	s.queue = append(s.queue, chunk)
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+// end records that request_end arrived, so next returns ok=false once
+// the queue has drained.
+func (s *inflightRequest) end() {
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+// close stops watching the owning connection's context once this
+// request no longer needs waking by it (drainRequestBody returned).
+func (s *inflightRequest) close() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+// next blocks until a chunk is queued, returning ok=false once end has
+// been called and every queued chunk has been returned, or once the
+// owning connection's context is cancelled.
+func (s *inflightRequest) next() (requestChunk, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.queue) == 0 {
+		if s.ended || s.cancelled {
+			return requestChunk{}, false
+		}
+		s.cond.Wait()
+	}
+	chunk := s.queue[0]
+	s.queue = s.queue[1:]
+	return chunk, true
+}
+
+func (c *Client) handleRequestStart(ctx context.Context, f Frame) {
+	pr, pw := io.Pipe()
+	stream := newInflightRequest(ctx)
+
+	c.inflightMu.Lock()
+	c.inflight[f.ID] = stream
+	c.inflightMu.Unlock()
+
+	go c.drainRequestBody(ctx, f.ID, stream, pw)
+	go c.forwardRequest(ctx, f, pr)
+}
+
+// drainRequestBody writes queued request chunks to pw in arrival order,
+// acking each one back to the peer only after it's written. It's the
+// only goroutine that can block on a slow local server, so that stall
+// never reaches the shared frame read loop.
+func (c *Client) drainRequestBody(ctx context.Context, id string, stream *inflightRequest, pw *io.PipeWriter) {
+	defer stream.close()
+
+	for {
+		chunk, ok := stream.next()
+		if !ok {
+			pw.Close()
+			return
+		}
+
+		if _, err := pw.Write(chunk.data); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		c.sendFrame(ctx, Frame{Type: frameRequestAck, ID: id, Seq: chunk.seq})
+	}
+}
+
+func (c *Client) handleRequestChunk(f Frame) {
+	c.inflightMu.Lock()
+	stream, ok := c.inflight[f.ID]
+	c.inflightMu.Unlock()
+	if !ok {
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(f.Chunk)
+	if err != nil {
+		if c.opts.Debug {
+			color.Red("Error decoding request chunk: %v", err)
+		}
+		return
+	}
+
+	stream.push(requestChunk{seq: f.Seq, data: data})
+}
+
+func (c *Client) handleRequestEnd(f Frame) {
+	c.inflightMu.Lock()
+	stream, ok := c.inflight[f.ID]
+	if ok {
+		delete(c.inflight, f.ID)
+	}
+	c.inflightMu.Unlock()
+
+	if ok {
+		stream.end()
+	}
+}
+
+func (c *Client) handleResponseAck(f Frame) {
+	c.ackMu.Lock()
+	ack, ok := c.responseAcks[f.ID]
+	c.ackMu.Unlock()
+
+	if ok {
+		ack.ack(f.Seq)
+	}
+}
+
+// forwardRequest relays one tunnel request to the local MCP server,
+// streaming body bytes in both directions rather than buffering them.
+// body is always the PipeReader half fed by drainRequestBody; closing
+// it on every exit path unblocks that drain (via io.ErrClosedPipe) so a
+// request rejected before httpClient.Do doesn't leave it writing into a
+// pipe nobody will ever read.
+func (c *Client) forwardRequest(ctx context.Context, f Frame, body *io.PipeReader) {
+	defer body.Close()
+
+	start := time.Now()
+
+	if c.opts.Debug {
+		color.Blue("← %s %s", f.Method, f.Path)
+	}
+
+	c.events.Publish(events.Event{
+		Kind:      events.Request,
+		RequestID: f.ID,
+		Method:    f.Method,
+		Path:      f.Path,
+	})
+
+	targetBase := c.opts.LocalURL
+	targetPath := f.Path
+
+	if c.opts.Router != nil {
+		clientID, _ := router.HeaderValue(f.Headers, "X-Client-Id")
+		action := c.opts.Router.Route(&router.Request{
+			Method:   f.Method,
+			Path:     f.Path,
+			Headers:  f.Headers,
+			ClientID: clientID,
+		})
+
+		switch action.Kind {
+		case router.ActionDeny:
+			c.sendResponseError(ctx, f, start, 403, "Denied by router rule")
+			return
+		case router.ActionRewritePath:
+			targetPath = action.Path
+		case router.ActionRouteToUpstream:
+			upstream, ok := c.opts.Upstreams[action.Upstream]
+			if !ok {
+				c.sendResponseError(ctx, f, start, 502, fmt.Sprintf("unknown upstream %q", action.Upstream))
+				return
+			}
+			targetBase = upstream
+		}
+	}
+
+	localURL, err := url.JoinPath(targetBase, targetPath)
+	if err != nil {
+		c.sendResponseError(ctx, f, start, 502, "Invalid path")
+		return
+	}
+
+	countedBody := &countingReader{r: body}
+	httpReq, err := http.NewRequestWithContext(ctx, f.Method, localURL, countedBody)
+	if err != nil {
+		c.sendResponseError(ctx, f, start, 502, err.Error())
+		return
+	}
+
+	for k, v := range f.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if c.opts.Debug {
+			color.Red("Error forwarding request: %v", err)
+		}
+		c.sendResponseError(ctx, f, start, 502, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	respHeaders := make(map[string]string)
+	for k, v := range resp.Header {
+		if len(v) > 0 {
+			respHeaders[k] = v[0]
+		}
+	}
+
+	c.sendFrame(ctx, Frame{
+		Type:    frameResponseStart,
+		ID:      f.ID,
+		Status:  resp.StatusCode,
+		Headers: respHeaders,
+	})
+
+	if c.opts.Debug {
+		color.Green("→ %d", resp.StatusCode)
+	}
+
+	ack := newAckTracker(ctx)
+	c.ackMu.Lock()
+	c.responseAcks[f.ID] = ack
+	c.ackMu.Unlock()
+	defer func() {
+		ack.close()
+		c.ackMu.Lock()
+		delete(c.responseAcks, f.ID)
+		c.ackMu.Unlock()
+	}()
+
+	bytesOut, err := c.streamResponseBody(ctx, f.ID, resp.Body, ack)
+	if err != nil {
+		c.events.Publish(events.Event{Kind: events.Error, Err: err})
+	}
+	c.sendFrame(ctx, Frame{Type: frameResponseEnd, ID: f.ID})
+
+	c.publishResponse(f, start, resp.StatusCode, countedBody.n, bytesOut)
+}
+
+// countingReader wraps a request body to tally the bytes the local
+// server actually reads off it, for the admin API's bytesIn counter.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+	return n, err
+}
+
+// streamResponseBody relays body as a sequence of response_chunk frames,
+// pausing once maxInFlightChunks are unacked by the peer.
+func (c *Client) streamResponseBody(ctx context.Context, id string, body io.Reader, ack *ackTracker) (int, error) {
+	buf := make([]byte, chunkSize)
+	seq := 0
+	total := 0
+
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			seq++
+			if seq > maxInFlightChunks {
+				ack.waitUntil(seq - maxInFlightChunks)
+			}
+
+			c.sendFrame(ctx, Frame{
+				Type:  frameResponseChunk,
+				ID:    id,
+				Seq:   seq,
+				Chunk: base64.StdEncoding.EncodeToString(buf[:n]),
+			})
+			total += n
+		}
+		if err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, err
+		}
+	}
+}
+
+func (c *Client) sendResponseError(ctx context.Context, f Frame, start time.Time, status int, message string) {
+	c.sendFrame(ctx, Frame{
+		Type:   frameResponseError,
+		ID:     f.ID,
+		Status: status,
+		Error:  message,
+	})
+	c.publishResponse(f, start, status, 0, 0)
+}
+
+func (c *Client) publishResponse(f Frame, start time.Time, status, bytesIn, bytesOut int) {
+	c.events.Publish(events.Event{
+		Kind:      events.Response,
+		RequestID: f.ID,
+		Method:    f.Method,
+		Path:      f.Path,
+		Status:    status,
+		BytesIn:   bytesIn,
+		Duration:  time.Since(start),
+		BytesOut:  bytesOut,
+	})
+}
+
+func (c *Client) sendFrame(ctx context.Context, f Frame) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		c.events.Publish(events.Event{Kind: events.Error, Err: err})
+		return
+	}
+
+	c.mu.Lock()
+	t := c.transport
+	c.mu.Unlock()
+
+	if t != nil {
+		if err := t.Write(ctx, data); err != nil {
+			c.events.Publish(events.Event{Kind: events.Error, Err: err})
+		}
+	}
+}
@@ -20,7 +20,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/cascade-protocol/splits/cli/internal/admin"
 	"github.com/cascade-protocol/splits/cli/internal/config"
+	"github.com/cascade-protocol/splits/cli/internal/events"
+	"github.com/cascade-protocol/splits/cli/internal/router"
+	"github.com/cascade-protocol/splits/cli/internal/transport"
 	"github.com/cascade-protocol/splits/cli/internal/tunnel"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
@@ -48,6 +52,27 @@ func makeFlags() []cli.Flag {
 			Aliases: []string{"d"},
 			Usage:   "Enable debug output",
 		},
+		&cli.StringFlag{
+			Name:  "transport",
+			Usage: "Transport to use for the tunnel connection: ws or quic",
+			Value: "ws",
+		},
+		&cli.StringFlag{
+			Name:  "rules",
+			Usage: "Path to a YAML/JSON rules file for request routing and access control",
+		},
+		&cli.StringSliceFlag{
+			Name:  "upstream",
+			Usage: "Additional upstream MCP server as name=url, referenced by route_to_upstream rules (repeatable)",
+		},
+		&cli.StringFlag{
+			Name:  "admin-listen",
+			Usage: "Bind address for the local admin/status HTTP API, e.g. 127.0.0.1:9090 (disabled if unset)",
+		},
+		&cli.BoolFlag{
+			Name:  "insecure-skip-verify",
+			Usage: "Skip Ed25519 signature verification of the service token (unsafe, for local/dev use only)",
+		},
 	}
 }
 
@@ -95,6 +120,26 @@ func runServe(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("local-url argument is required")
 	}
 
+	tr, err := parseTransport(cmd.String("transport"))
+	if err != nil {
+		return err
+	}
+
+	upstreams, err := parseUpstreams(cmd.StringSlice("upstream"))
+	if err != nil {
+		return err
+	}
+
+	rulesPath := cmd.String("rules")
+	var rt router.Router
+	if rulesPath != "" {
+		rules, err := router.LoadRulesFile(rulesPath)
+		if err != nil {
+			return fmt.Errorf("loading rules file: %w", err)
+		}
+		rt = router.New(rules...)
+	}
+
 	// Parse token
 	token, err := config.ParseToken(rawToken)
 	if err != nil {
@@ -103,6 +148,14 @@ func runServe(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	if cmd.Bool("insecure-skip-verify") {
+		color.New(color.FgRed, color.Bold).Println("⚠ WARNING: --insecure-skip-verify is set, the token's signature is NOT being verified")
+	} else if err := config.VerifyToken(token); err != nil {
+		color.Red("Error: Token signature verification failed")
+		color.New(color.FgHiBlack).Println("Pass --insecure-skip-verify to bypass this for local/dev tokens.")
+		return err
+	}
+
 	// Normalize local URL
 	if !strings.HasPrefix(localURL, "http") {
 		localURL = "http://" + localURL
@@ -131,36 +184,112 @@ func runServe(ctx context.Context, cmd *cli.Command) error {
 		cancel()
 	}()
 
+	// Reload the rules file on SIGHUP without dropping the connection.
+	if rulesPath != "" {
+		hupCh := make(chan os.Signal, 1)
+		signal.Notify(hupCh, syscall.SIGHUP)
+		go func() {
+			for range hupCh {
+				rules, err := router.LoadRulesFile(rulesPath)
+				if err != nil {
+					color.Red("Error reloading rules: %v", err)
+					continue
+				}
+				rt.SetRules(rules)
+				color.Green("Reloaded %d rule(s) from %s", len(rules), rulesPath)
+			}
+		}()
+	}
+
+	bus := events.NewBus()
+	go logEvents(bus, debug, token.ServiceName)
+
+	adminListen := cmd.String("admin-listen")
+	if adminListen != "" {
+		adminServer := admin.New(admin.Options{
+			ListenAddr: adminListen,
+			Token:      token,
+			Events:     bus,
+			Disconnect: cancel,
+		})
+		go func() {
+			if err := adminServer.Run(ctx); err != nil {
+				color.Red("Admin API error: %v", err)
+			}
+		}()
+	}
+
 	// Create and run tunnel client
 	client := tunnel.NewClient(tunnel.Options{
-		Token:    token,
-		LocalURL: localURL,
-		Debug:    debug,
-		OnConnect: func() {
+		Token:     token,
+		LocalURL:  localURL,
+		Debug:     debug,
+		Transport: tr,
+		Router:    rt,
+		Upstreams: upstreams,
+		Events:    bus,
+	})
+
+	return client.Connect(ctx)
+}
+
+// logEvents subscribes to bus and renders connection and request events
+// as the colored stdout log the CLI has always printed.
+func logEvents(bus *events.Bus, debug bool, serviceName string) {
+	for e := range bus.Subscribe() {
+		switch e.Kind {
+		case events.Connected:
 			color.Green("✓ Connected to Cascade Market")
 			color.New(color.FgHiBlack).Println("  Your MCP is now available at:")
-			fmt.Printf("  https://%s.mcps.market.cascade.fyi\n", token.ServiceName)
+			fmt.Printf("  https://%s.mcps.market.cascade.fyi\n", serviceName)
 			fmt.Println()
 			color.New(color.FgHiBlack).Println("Press Ctrl+C to disconnect")
 			fmt.Println()
-		},
-		OnDisconnect: func(code int, reason string) {
-			color.Yellow("Disconnected (%d: %s)", code, reason)
-		},
-		OnError: func(err error) {
-			color.Red("Error: %v", err)
-		},
-		OnRequest: func(method, path string) {
+		case events.Disconnected:
+			color.Yellow("Disconnected (%d: %s)", e.Code, e.Reason)
+		case events.Error:
+			color.Red("Error: %v", e.Err)
+		case events.Request:
 			if !debug {
 				timestamp := color.HiBlackString("[%s]", timeNow())
-				fmt.Printf("%s %s %s\n", timestamp, color.BlueString(method), path)
+				fmt.Printf("%s %s %s\n", timestamp, color.BlueString(e.Method), e.Path)
 			}
-		},
-	})
-
-	return client.Connect(ctx)
+		}
+	}
 }
 
 func timeNow() string {
 	return time.Now().Format("15:04:05")
 }
+
+// parseUpstreams turns repeated --upstream name=url flags into the map
+// tunnel.Options.Upstreams expects.
+func parseUpstreams(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	upstreams := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, url, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid --upstream %q (expected name=url)", entry)
+		}
+		upstreams[name] = url
+	}
+	return upstreams, nil
+}
+
+// parseTransport maps the --transport flag to a concrete transport.
+// Transport. A QUIC transport still falls back to WebSocket automatically
+// if its initial dial fails; this only rejects outright unknown values.
+func parseTransport(name string) (transport.Transport, error) {
+	switch name {
+	case "", "ws":
+		return transport.NewWebSocketTransport(), nil
+	case "quic":
+		return transport.NewQUICTransport(), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q (expected ws or quic)", name)
+	}
+}